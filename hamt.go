@@ -0,0 +1,197 @@
+package safemap
+
+import "math/bits"
+
+// hamtBits is the number of hash bits consumed per trie level (32-way
+// branching), and hamtMaxDepth is the number of levels needed to consume a
+// full 64-bit hash before entries must be chained as collisions.
+const (
+	hamtBits     = 5
+	hamtMaxDepth = (64 + hamtBits - 1) / hamtBits
+)
+
+// hamtEntry is a single key/value pair stored in a leaf, along with the
+// hash it was inserted under so it can be pushed further down the trie
+// without rehashing.
+type hamtEntry[K comparable, V any] struct {
+	key   K
+	hash  uint64
+	value V
+}
+
+// hamtLeaf holds one or more entries that share a hash prefix. It normally
+// holds a single entry; it holds more only when two keys hash identically
+// all the way to hamtMaxDepth.
+type hamtLeaf[K comparable, V any] struct {
+	entries []hamtEntry[K, V]
+}
+
+// hamtNode is an interior node of the persistent hash-array-mapped trie.
+// bitmap marks which of the 32 possible slots at this level are populated;
+// children holds one entry per set bit, in bitmap order, each either a
+// *hamtLeaf[K,V] or a *hamtNode[K,V]. Nodes are never mutated in place:
+// every insert/delete returns a new node with the affected spine
+// path-copied, leaving any other goroutine's view of the old root intact.
+type hamtNode[K comparable, V any] struct {
+	bitmap   uint32
+	children []any
+}
+
+func hamtSlot(hash uint64, depth int) uint32 {
+	return uint32(hash>>(depth*hamtBits)) & (1<<hamtBits - 1)
+}
+
+func hamtChildIndex(bitmap, bit uint32) int {
+	return bits.OnesCount32(bitmap & (bit - 1))
+}
+
+// hamtInsertNode returns a copy of n with key/value inserted (or updated,
+// if key is already present), path-copying only the nodes on the way to
+// the affected slot.
+func hamtInsertNode[K comparable, V any](n *hamtNode[K, V], hash uint64, depth int, key K, value V) *hamtNode[K, V] {
+	bit := uint32(1) << hamtSlot(hash, depth)
+	pos := hamtChildIndex(n.bitmap, bit)
+
+	if n.bitmap&bit == 0 {
+		children := make([]any, len(n.children)+1)
+		copy(children, n.children[:pos])
+		children[pos] = &hamtLeaf[K, V]{entries: []hamtEntry[K, V]{{key: key, hash: hash, value: value}}}
+		copy(children[pos+1:], n.children[pos:])
+		return &hamtNode[K, V]{bitmap: n.bitmap | bit, children: children}
+	}
+
+	children := append([]any(nil), n.children...)
+	children[pos] = hamtInsertChild[K, V](children[pos], hash, depth+1, key, value)
+	return &hamtNode[K, V]{bitmap: n.bitmap, children: children}
+}
+
+// hamtInsertChild inserts into a child slot that may be a leaf or a node,
+// splitting a single-entry leaf into a node when two distinct keys land in
+// the same slot below hamtMaxDepth.
+func hamtInsertChild[K comparable, V any](child any, hash uint64, depth int, key K, value V) any {
+	switch c := child.(type) {
+	case *hamtNode[K, V]:
+		return hamtInsertNode(c, hash, depth, key, value)
+	case *hamtLeaf[K, V]:
+		for i, e := range c.entries {
+			if e.key == key {
+				entries := append([]hamtEntry[K, V](nil), c.entries...)
+				entries[i] = hamtEntry[K, V]{key: key, hash: hash, value: value}
+				return &hamtLeaf[K, V]{entries: entries}
+			}
+		}
+
+		if depth >= hamtMaxDepth || len(c.entries) > 1 {
+			entries := append(append([]hamtEntry[K, V](nil), c.entries...), hamtEntry[K, V]{key: key, hash: hash, value: value})
+			return &hamtLeaf[K, V]{entries: entries}
+		}
+
+		existing := c.entries[0]
+		node := &hamtNode[K, V]{}
+		var grown any = node
+		grown = hamtInsertChild[K, V](grown, existing.hash, depth, existing.key, existing.value)
+		grown = hamtInsertChild[K, V](grown, hash, depth, key, value)
+		return grown
+	default:
+		return &hamtLeaf[K, V]{entries: []hamtEntry[K, V]{{key: key, hash: hash, value: value}}}
+	}
+}
+
+// hamtGet looks up key, descending through n using successive hamtBits
+// chunks of hash.
+func hamtGet[K comparable, V any](n *hamtNode[K, V], hash uint64, depth int, key K) (value V, ok bool) {
+	bit := uint32(1) << hamtSlot(hash, depth)
+	if n.bitmap&bit == 0 {
+		return value, false
+	}
+
+	switch c := n.children[hamtChildIndex(n.bitmap, bit)].(type) {
+	case *hamtLeaf[K, V]:
+		for _, e := range c.entries {
+			if e.key == key {
+				return e.value, true
+			}
+		}
+		return value, false
+	case *hamtNode[K, V]:
+		return hamtGet(c, hash, depth+1, key)
+	default:
+		return value, false
+	}
+}
+
+// hamtDeleteNode returns a copy of n with key removed, path-copying only
+// the nodes on the way to the affected slot. It returns n unchanged (same
+// pointer) if key is not present.
+func hamtDeleteNode[K comparable, V any](n *hamtNode[K, V], hash uint64, depth int, key K) *hamtNode[K, V] {
+	bit := uint32(1) << hamtSlot(hash, depth)
+	if n.bitmap&bit == 0 {
+		return n
+	}
+
+	pos := hamtChildIndex(n.bitmap, bit)
+	newChild := hamtDeleteChild[K, V](n.children[pos], hash, depth+1, key)
+	if newChild == n.children[pos] {
+		return n
+	}
+
+	if newChild == nil {
+		children := make([]any, len(n.children)-1)
+		copy(children, n.children[:pos])
+		copy(children[pos:], n.children[pos+1:])
+		return &hamtNode[K, V]{bitmap: n.bitmap &^ bit, children: children}
+	}
+
+	children := append([]any(nil), n.children...)
+	children[pos] = newChild
+	return &hamtNode[K, V]{bitmap: n.bitmap, children: children}
+}
+
+// hamtDeleteChild deletes key from a child slot that may be a leaf or a
+// node, returning nil if the slot becomes empty.
+func hamtDeleteChild[K comparable, V any](child any, hash uint64, depth int, key K) any {
+	switch c := child.(type) {
+	case *hamtNode[K, V]:
+		newNode := hamtDeleteNode(c, hash, depth, key)
+		if newNode.bitmap == 0 {
+			return nil
+		}
+		return newNode
+	case *hamtLeaf[K, V]:
+		for i, e := range c.entries {
+			if e.key != key {
+				continue
+			}
+			if len(c.entries) == 1 {
+				return nil
+			}
+			entries := make([]hamtEntry[K, V], 0, len(c.entries)-1)
+			entries = append(entries, c.entries[:i]...)
+			entries = append(entries, c.entries[i+1:]...)
+			return &hamtLeaf[K, V]{entries: entries}
+		}
+		return c
+	default:
+		return child
+	}
+}
+
+// hamtRange calls f(key, value) for every entry reachable from n, stopping
+// as soon as f returns false. Iteration order is unspecified.
+func hamtRange[K comparable, V any](n *hamtNode[K, V], f func(K, V) bool) bool {
+	for _, child := range n.children {
+		switch c := child.(type) {
+		case *hamtLeaf[K, V]:
+			for _, e := range c.entries {
+				if !f(e.key, e.value) {
+					return false
+				}
+			}
+		case *hamtNode[K, V]:
+			if !hamtRange(c, f) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package safemap
+
+import (
+	"cmp"
+	"hash/maphash"
+	"strconv"
+)
+
+// hashKey hashes a cmp.Ordered key under seed. A key of type string is
+// hashed directly; any other key is converted by toFloat64 and hashed via
+// the shortest round-trip decimal form of that float64, so distinct keys
+// that convert to the same float64 share a hash.
+func hashKey[K cmp.Ordered](key K, seed maphash.Seed) uint64 {
+	var h maphash.Hash
+	h.SetSeed(seed)
+
+	if s, ok := any(key).(string); ok {
+		h.WriteString(s)
+	} else {
+		h.WriteString(strconv.FormatFloat(toFloat64(key), 'g', -1, 64))
+	}
+
+	return h.Sum64()
+}
+
+// toFloat64 converts a numeric key to a float64 for hashing. It matches
+// only the predeclared integer and floating-point types: a key of a named
+// type (such as type ID string or type N int) converts to 0, so all keys
+// of such a type share one hash.
+func toFloat64[K cmp.Ordered](key K) float64 {
+	switch v := any(key).(type) {
+	case int:
+		return float64(v)
+	case int8:
+		return float64(v)
+	case int16:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint:
+		return float64(v)
+	case uint8:
+		return float64(v)
+	case uint16:
+		return float64(v)
+	case uint32:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 0
+	}
+}
@@ -3,8 +3,11 @@ package safemap
 import (
 	"cmp"
 	"container/list"
+	"hash/maphash"
 	"maps"
+	"reflect"
 	"sync"
+	"sync/atomic"
 )
 
 const Ordered = true
@@ -12,10 +15,17 @@ const Ordered = true
 // M is a thread-safe map with additional features.
 type M[K cmp.Ordered, V any] struct {
 	sync.RWMutex
-	m        map[K]V
-	ordered  bool
-	keys     *list.List
-	keyIndex map[K]*list.Element
+	m          map[K]V
+	ordered    bool
+	keys       *list.List
+	keyIndex   map[K]*list.Element
+	eq         func(a, b V) bool
+	sortedKeys bool
+	tree       *treapNode[K]
+
+	snapshotting bool
+	hashSeed     maphash.Seed
+	hamtRoot     atomic.Pointer[hamtNode[K, V]]
 }
 
 // New creates a new M.
@@ -25,6 +35,7 @@ func New[K cmp.Ordered, V any](ordered ...bool) *M[K, V] {
 		m: make(map[K]V),
 		keys: list.New(),
 		keyIndex: make(map[K]*list.Element),
+		eq: func(a, b V) bool { return reflect.DeepEqual(a, b) },
 	}
 
 	if len(ordered) > 0 && ordered[0] {
@@ -33,6 +44,15 @@ func New[K cmp.Ordered, V any](ordered ...bool) *M[K, V] {
 	return sm
 }
 
+// NewComparable creates a new M whose CompareAndSwap and CompareAndDelete
+// compare values with the == operator instead of reflect.DeepEqual, avoiding
+// reflection overhead for value types that are comparable.
+func NewComparable[K cmp.Ordered, V comparable](ordered ...bool) *M[K, V] {
+	sm := New[K, V](ordered...)
+	sm.eq = func(a, b V) bool { return a == b }
+	return sm
+}
+
 // Exists - checks if a key exists in the map
 func (sm *M[K, V]) Exists(key K) (exists bool) {
 	sm.RLock()
@@ -51,17 +71,35 @@ func (sm *M[K, V]) Load(key K) (value V, ok bool) {
 	return value, ok
 }
 
+// trackKey registers key in the insertion-order list, or, if it's already
+// tracked, leaves its existing position alone.
+func (sm *M[K, V]) trackKey(key K) {
+	if elem, exists := sm.keyIndex[key]; !exists {
+		sm.keyIndex[key] = sm.keys.PushBack(key)
+	} else {
+		elem.Value = key
+	}
+}
+
+// trackNewKey calls trackKey and, in sorted mode, also inserts key into
+// the treap — but only when alreadyPresent is false, since the treap must
+// never gain a second entry for a key it already holds.
+func (sm *M[K, V]) trackNewKey(key K, alreadyPresent bool) {
+	sm.trackKey(key)
+	if sm.sortedKeys && !alreadyPresent {
+		sm.tree = treapInsert(sm.tree, key)
+	}
+}
+
 // Store - stores a value in the map
 func (sm *M[K, V]) Store(key K, value V) {
 	sm.Lock()
 	defer sm.Unlock()
 
-    if elem, exists := sm.keyIndex[key]; !exists {
-        sm.keyIndex[key] = sm.keys.PushBack(key)
-    } else {
-        elem.Value = key
-    }
+    _, existed := sm.m[key]
+    sm.trackNewKey(key, existed)
     sm.m[key] = value
+    sm.snapshotInsert(key, value)
 }
 
 // Delete - deletes a key from the map
@@ -78,80 +116,116 @@ func (sm *M[K, V]) removeKey(key K) {
 		sm.keys.Remove(elem)
 		delete(sm.keyIndex, key)
 	}
+	if sm.sortedKeys {
+		if _, exists := sm.m[key]; exists {
+			sm.tree = treapDelete(sm.tree, key)
+		}
+	}
 	delete(sm.m, key)
+	sm.snapshotDelete(key)
 }
 
-// Add - adds a value to the existing value for numeric types
-func (sm *M[K, V]) Add(key K, value V) {
+// Update performs an arbitrary read-modify-write on key under a single
+// lock acquisition. f receives the current value (and whether it was
+// present) and returns the new value together with whether the key should
+// be kept; returning keep=false deletes the key instead of storing it.
+// Update subsumes idioms like counter increment, store-if-absent, and
+// compute-then-delete without exposing the internal map or requiring
+// multiple locked calls.
+// Note: f runs with sm's write lock held, so f must not call back into sm.
+func (sm *M[K, V]) Update(key K, f func(old V, present bool) (new V, keep bool)) {
 	sm.Lock()
 	defer sm.Unlock()
 
-	switch v := any(sm.m[key]).(type) {
-	case int:
-		sm.m[key] = any(v + any(value).(int)).(V)
-		// sm.m[key] = any(v + any(value).(int)).(V)
-	case int8:
-		sm.m[key] = any(v + any(value).(int8)).(V)
-	case int16:
-		sm.m[key] = any(v + any(value).(int16)).(V)
-	case int32:
-		sm.m[key] = any(v + any(value).(int32)).(V)
-	case int64:
-		sm.m[key] = any(v + any(value).(int64)).(V)
-	case uint:
-		sm.m[key] = any(v + any(value).(uint)).(V)
-	case uint8:
-		sm.m[key] = any(v + any(value).(uint8)).(V)
-	case uint16:
-		sm.m[key] = any(v + any(value).(uint16)).(V)
-	case uint32:
-		sm.m[key] = any(v + any(value).(uint32)).(V)
-	case uint64:
-		sm.m[key] = any(v + any(value).(uint64)).(V)
-	case float32:
-		sm.m[key] = any(v + any(value).(float32)).(V)
-	case float64:
-		sm.m[key] = any(v + any(value).(float64)).(V)
-	case string:
-		sm.m[key] = any(v + any(value).(string)).(V)
-	default:
-		panic("unsupported type")
+	old, present := sm.m[key]
+	value, keep := f(old, present)
+	if !keep {
+		sm.removeKey(key)
+		return
 	}
+
+	sm.trackNewKey(key, present)
+	sm.m[key] = value
+	sm.snapshotInsert(key, value)
+}
+
+// Add - adds a value to the existing value for numeric types
+//
+// Deprecated: for numeric V, use NewNumeric and NumericM.Add instead, which
+// operate on V directly with + instead of a runtime type switch. Add will
+// be removed in a future release. String counters have no migration target
+// (see Numeric's doc comment) and should keep using this Add.
+func (sm *M[K, V]) Add(key K, value V) {
+	sm.Update(key, func(old V, _ bool) (V, bool) {
+		switch v := any(old).(type) {
+		case int:
+			return any(v + any(value).(int)).(V), true
+		case int8:
+			return any(v + any(value).(int8)).(V), true
+		case int16:
+			return any(v + any(value).(int16)).(V), true
+		case int32:
+			return any(v + any(value).(int32)).(V), true
+		case int64:
+			return any(v + any(value).(int64)).(V), true
+		case uint:
+			return any(v + any(value).(uint)).(V), true
+		case uint8:
+			return any(v + any(value).(uint8)).(V), true
+		case uint16:
+			return any(v + any(value).(uint16)).(V), true
+		case uint32:
+			return any(v + any(value).(uint32)).(V), true
+		case uint64:
+			return any(v + any(value).(uint64)).(V), true
+		case float32:
+			return any(v + any(value).(float32)).(V), true
+		case float64:
+			return any(v + any(value).(float64)).(V), true
+		case string:
+			return any(v + any(value).(string)).(V), true
+		default:
+			panic("unsupported type")
+		}
+	})
 }
 
 // Sub - subtracts a value from the existing value for numeric types
+//
+// Deprecated: use NewNumeric and NumericM.Sub instead, which operate on V
+// directly with - instead of a runtime type switch. Sub will be removed in
+// a future release.
 func (sm *M[K, V]) Sub(key K, value V) {
-	sm.Lock()
-	defer sm.Unlock()
-
-	switch v := any(sm.m[key]).(type) {
-	case int:
-		sm.m[key] = any(v - any(value).(int)).(V)
-	case int8:
-		sm.m[key] = any(v - any(value).(int8)).(V)
-	case int16:
-		sm.m[key] = any(v - any(value).(int16)).(V)
-	case int32:
-		sm.m[key] = any(v - any(value).(int32)).(V)
-	case int64:
-		sm.m[key] = any(v - any(value).(int64)).(V)
-	case uint:
-		sm.m[key] = any(v - any(value).(uint)).(V)
-	case uint8:
-		sm.m[key] = any(v - any(value).(uint8)).(V)
-	case uint16:
-		sm.m[key] = any(v - any(value).(uint16)).(V)
-	case uint32:
-		sm.m[key] = any(v - any(value).(uint32)).(V)
-	case uint64:
-		sm.m[key] = any(v - any(value).(uint64)).(V)
-	case float32:
-		sm.m[key] = any(v - any(value).(float32)).(V)
-	case float64:
-		sm.m[key] = any(v - any(value).(float64)).(V)
-	default:
-		panic("unsupported type")
-	}
+	sm.Update(key, func(old V, _ bool) (V, bool) {
+		switch v := any(old).(type) {
+		case int:
+			return any(v - any(value).(int)).(V), true
+		case int8:
+			return any(v - any(value).(int8)).(V), true
+		case int16:
+			return any(v - any(value).(int16)).(V), true
+		case int32:
+			return any(v - any(value).(int32)).(V), true
+		case int64:
+			return any(v - any(value).(int64)).(V), true
+		case uint:
+			return any(v - any(value).(uint)).(V), true
+		case uint8:
+			return any(v - any(value).(uint8)).(V), true
+		case uint16:
+			return any(v - any(value).(uint16)).(V), true
+		case uint32:
+			return any(v - any(value).(uint32)).(V), true
+		case uint64:
+			return any(v - any(value).(uint64)).(V), true
+		case float32:
+			return any(v - any(value).(float32)).(V), true
+		case float64:
+			return any(v - any(value).(float64)).(V), true
+		default:
+			panic("unsupported type")
+		}
+	})
 }
 
 // LoadAndDelete - loads a value from the map and deletes the key
@@ -176,14 +250,9 @@ func (sm *M[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
 		return actual, true
 	}
 
+	sm.trackNewKey(key, false)
 	sm.m[key] = value
-	if sm.ordered {
-		if elem, exists := sm.keyIndex[key]; !exists {
-			sm.keyIndex[key] = sm.keys.PushBack(key)
-		} else {
-			elem.Value = key
-		}
-	}
+	sm.snapshotInsert(key, value)
 	return value, false
 }
 
@@ -193,15 +262,57 @@ func (sm *M[K, V]) Swap(key K, value V) (previous V, loaded bool) {
 	defer sm.Unlock()
 
 	previous, loaded = sm.m[key]
+	sm.trackNewKey(key, loaded)
 	sm.m[key] = value
+	sm.snapshotInsert(key, value)
 	return previous, loaded
 }
 
+// CompareAndSwap - swaps the old and new values for key if the value stored
+// in the map is equal to old, as reported by M's equality function (== for
+// maps created with NewComparable, reflect.DeepEqual otherwise).
+func (sm *M[K, V]) CompareAndSwap(key K, old, new V) bool {
+	sm.Lock()
+	defer sm.Unlock()
+
+	current, ok := sm.m[key]
+	if !ok || !sm.eq(current, old) {
+		return false
+	}
+
+	sm.trackNewKey(key, true)
+	sm.m[key] = new
+	sm.snapshotInsert(key, new)
+	return true
+}
+
+
+// CompareAndDelete - deletes the entry for key if its value is equal to old,
+// as reported by M's equality function, removing the key from the
+// ordered-keys bookkeeping as well.
+func (sm *M[K, V]) CompareAndDelete(key K, old V) (deleted bool) {
+	sm.Lock()
+	defer sm.Unlock()
+
+	current, ok := sm.m[key]
+	if !ok || !sm.eq(current, old) {
+		return false
+	}
+
+	sm.removeKey(key)
+	return true
+}
+
 // Range - ranges over the map by calling a function for each key-value pair
 func (sm *M[K, V]) Range(f func(K, V) bool) {
 	sm.RLock()
 	defer sm.RUnlock()
 
+	if sm.sortedKeys {
+		treapInOrder(sm.tree, sm.m, f)
+		return
+	}
+
 	if sm.ordered {
 		for e := sm.keys.Front(); e != nil; e = e.Next() {
 			k := e.Value.(K)
@@ -263,6 +374,8 @@ func (sm *M[K, V]) Clear() {
 	sm.m = make(map[K]V)
 	sm.keys.Init()
 	sm.keyIndex = make(map[K]*list.Element)
+	sm.tree = nil
+	sm.snapshotClear()
 }
 
 // Len - returns the length of the map
@@ -280,6 +393,14 @@ func (sm *M[K, V]) Keys() []K {
 
 	keys := make([]K, sm.keys.Len())
 	i := 0
+	if sm.sortedKeys {
+		treapInOrder(sm.tree, sm.m, func(k K, _ V) bool {
+			keys[i] = k
+			i++
+			return true
+		})
+		return keys
+	}
 	if sm.ordered {
 		for e := sm.keys.Front(); e != nil; e = e.Next() {
 			keys[i] = e.Value.(K)
@@ -302,19 +423,29 @@ func (sm *M[K, V]) Map() map[K]V {
 	return maps.Clone(sm.m)
 }
 
-// KeysInRange - returns the slice of keys of the map in a range
-// Note: works only for positive numeric types
+// KeysInRange - returns the slice of keys of the map in the range [from, to).
+// from and to are always treated as real bounds, including the zero value of
+// K, so a signed key such as -5 or 0 can be used as an endpoint; there is no
+// "unbounded" sentinel. Callers that need an unbounded scan should use a
+// sorted-mode map (see NewSorted) and First/Last/Floor/Ceiling instead.
 func (sm *M[K, V]) KeysInRange(from, to K) []K {
 	sm.RLock()
 	defer sm.RUnlock()
 
-	var zero K
 	filtered := make([]K, 0, len(sm.m))
 
+	if sm.sortedKeys {
+		treapRange(sm.tree, sm.m, from, to, func(k K, _ V) bool {
+			filtered = append(filtered, k)
+			return true
+		})
+		return filtered
+	}
+
 	if sm.ordered {
 		for e := sm.keys.Front(); e != nil; e = e.Next() {
 			k := e.Value.(K)
-			if (from == zero || k >= from) && (to == zero || k < to) {
+			if k >= from && k < to {
 				filtered = append(filtered, k)
 			}
 		}
@@ -322,7 +453,7 @@ func (sm *M[K, V]) KeysInRange(from, to K) []K {
 	}
 
 	for k := range sm.m {
-		if (from == zero || k >= from) && (to == zero || k < to) {
+		if k >= from && k < to {
 			filtered = append(filtered, k)
 		}
 	}
@@ -1,7 +1,9 @@
 package safemap
 
 import (
+	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -60,6 +62,60 @@ func TestSafeMap_Sub(t *testing.T) {
 	}
 }
 
+func TestSafeMap_Update(t *testing.T) {
+	sm := New[int, int]()
+
+	sm.Update(1, func(old int, present bool) (int, bool) {
+		if present {
+			t.Errorf("expected key 1 to not be present yet")
+		}
+		return old + 10, true
+	})
+
+	value, ok := sm.Load(1)
+	if !ok || value != 10 {
+		t.Errorf("expected 10, got '%v'", value)
+	}
+
+	sm.Update(1, func(old int, present bool) (int, bool) {
+		if !present || old != 10 {
+			t.Errorf("expected old value 10, got '%v' (present=%v)", old, present)
+		}
+		return old, false
+	})
+
+	if sm.Exists(1) {
+		t.Errorf("expected key 1 to be deleted")
+	}
+}
+
+func TestNumericM_AddAndSub(t *testing.T) {
+	sm := NewNumeric[int, int]()
+	sm.Store(1, 10)
+	sm.Add(1, 5)
+
+	value, ok := sm.Load(1)
+	if !ok || value != 15 {
+		t.Errorf("expected 15, got '%v'", value)
+	}
+
+	sm.Sub(1, 20)
+	value, ok = sm.Load(1)
+	if !ok || value != -5 {
+		t.Errorf("expected -5, got '%v'", value)
+	}
+}
+
+func TestNumericM_AddToMissingKey(t *testing.T) {
+	sm := NewNumeric[int, float64]()
+	sm.Add(1, 2.5)
+
+	value, ok := sm.Load(1)
+	if !ok || value != 2.5 {
+		t.Errorf("expected 2.5, got '%v'", value)
+	}
+}
+
 func TestSafeMap_LoadAndDelete(t *testing.T) {
 	sm := New[int, string]()
 	sm.Store(1, "one")
@@ -102,6 +158,126 @@ func TestSafeMap_Swap(t *testing.T) {
 	}
 }
 
+func TestSafeMap_CompareAndSwap(t *testing.T) {
+	sm := New[int, string]()
+	sm.Store(1, "one")
+
+	if sm.CompareAndSwap(1, "wrong", "two") {
+		t.Errorf("expected CompareAndSwap to fail on mismatched old value")
+	}
+
+	if !sm.CompareAndSwap(1, "one", "two") {
+		t.Errorf("expected CompareAndSwap to succeed on matching old value")
+	}
+
+	value, ok := sm.Load(1)
+	if !ok || value != "two" {
+		t.Errorf("expected 'two', got '%v'", value)
+	}
+
+	if sm.CompareAndSwap(2, "missing", "anything") {
+		t.Errorf("expected CompareAndSwap to fail for a missing key")
+	}
+}
+
+func TestSafeMap_CompareAndDelete(t *testing.T) {
+	sm := New[int, string]()
+	sm.Store(1, "one")
+
+	if sm.CompareAndDelete(1, "wrong") {
+		t.Errorf("expected CompareAndDelete to fail on mismatched old value")
+	}
+
+	if !sm.CompareAndDelete(1, "one") {
+		t.Errorf("expected CompareAndDelete to succeed on matching old value")
+	}
+
+	if sm.Exists(1) {
+		t.Errorf("expected key 1 to be deleted")
+	}
+}
+
+func TestSafeMap_CompareAndSwap_Comparable(t *testing.T) {
+	sm := NewComparable[int, int]()
+	sm.Store(1, 10)
+
+	if sm.CompareAndSwap(1, 5, 20) {
+		t.Errorf("expected CompareAndSwap to fail on mismatched old value")
+	}
+
+	if !sm.CompareAndSwap(1, 10, 20) {
+		t.Errorf("expected CompareAndSwap to succeed on matching old value")
+	}
+
+	value, ok := sm.Load(1)
+	if !ok || value != 20 {
+		t.Errorf("expected 20, got '%v'", value)
+	}
+}
+
+// TestSafeMap_CompareAndSwap_Concurrent mirrors sync.Map's own CompareAndSwap
+// test: under contention, exactly one of many racing swaps from the same old
+// value must succeed.
+func TestSafeMap_CompareAndSwap_Concurrent(t *testing.T) {
+	const goroutines = 64
+
+	sm := NewComparable[int, int]()
+	sm.Store(1, 0)
+
+	var successes int64
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			if sm.CompareAndSwap(1, 0, 1) {
+				atomic.AddInt64(&successes, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Errorf("expected exactly one successful CompareAndSwap, got %d", successes)
+	}
+
+	value, _ := sm.Load(1)
+	if value != 1 {
+		t.Errorf("expected 1, got '%v'", value)
+	}
+}
+
+// TestSafeMap_CompareAndDelete_Concurrent mirrors sync.Map's own
+// CompareAndDelete test: under contention, exactly one of many racing
+// deletes for the same old value must succeed.
+func TestSafeMap_CompareAndDelete_Concurrent(t *testing.T) {
+	const goroutines = 64
+
+	sm := NewComparable[int, int]()
+	sm.Store(1, 0)
+
+	var successes int64
+	var wg sync.WaitGroup
+	wg.Add(goroutines)
+	for i := 0; i < goroutines; i++ {
+		go func() {
+			defer wg.Done()
+			if sm.CompareAndDelete(1, 0) {
+				atomic.AddInt64(&successes, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Errorf("expected exactly one successful CompareAndDelete, got %d", successes)
+	}
+
+	if sm.Exists(1) {
+		t.Errorf("expected key 1 to be deleted")
+	}
+}
+
 func TestSafeMap_Range(t *testing.T) {
 	sm := New[int, string]()
 	sm.Store(1, "one")
@@ -139,6 +315,19 @@ func TestSafeMap_KeysInRange(t *testing.T) {
 		t.Errorf("expected keys 1 and 2, got '%v'", keys)
 	}
 }
+
+func TestSafeMap_KeysInRange_SignedZeroBound(t *testing.T) {
+	sm := New[int, int]()
+	for _, k := range []int{-5, -1, 0, 3, 7} {
+		sm.Store(k, k)
+	}
+
+	keys := sm.KeysInRange(-5, 0)
+	sort.Ints(keys)
+	if len(keys) != 2 || keys[0] != -5 || keys[1] != -1 {
+		t.Errorf("expected [-5 -1], got %v", keys)
+	}
+}
 func TestSafeMap_StoreAndLoad_Ordered(t *testing.T) {
 	sm := New[int, string](Ordered)
 	sm.Store(1, "one")
@@ -0,0 +1,163 @@
+package safemap
+
+import (
+	"cmp"
+	"hash/maphash"
+	"sync"
+)
+
+// Sharded is a thread-safe map that partitions entries across a fixed
+// number of independently-locked buckets to reduce RWMutex contention
+// under concurrent Store/Delete, at the cost of no ordering guarantees:
+// unlike M(Ordered), a Sharded map never preserves insertion order.
+type Sharded[K cmp.Ordered, V any] struct {
+	seed   maphash.Seed
+	shards []*shard[K, V]
+}
+
+type shard[K cmp.Ordered, V any] struct {
+	sync.RWMutex
+	m map[K]V
+}
+
+// NewSharded creates a new Sharded map with n shards. n is rounded up to 1.
+func NewSharded[K cmp.Ordered, V any](n int) *Sharded[K, V] {
+	if n < 1 {
+		n = 1
+	}
+
+	sm := &Sharded[K, V]{
+		seed:   maphash.MakeSeed(),
+		shards: make([]*shard[K, V], n),
+	}
+	for i := range sm.shards {
+		sm.shards[i] = &shard[K, V]{m: make(map[K]V)}
+	}
+	return sm
+}
+
+// shardFor returns the shard responsible for key.
+func (sm *Sharded[K, V]) shardFor(key K) *shard[K, V] {
+	return sm.shards[hashKey(key, sm.seed)%uint64(len(sm.shards))]
+}
+
+// Exists - checks if a key exists in the map
+func (sm *Sharded[K, V]) Exists(key K) bool {
+	sh := sm.shardFor(key)
+	sh.RLock()
+	defer sh.RUnlock()
+
+	_, exists := sh.m[key]
+	return exists
+}
+
+// Load - loads a value from the map
+func (sm *Sharded[K, V]) Load(key K) (value V, ok bool) {
+	sh := sm.shardFor(key)
+	sh.RLock()
+	defer sh.RUnlock()
+
+	value, ok = sh.m[key]
+	return value, ok
+}
+
+// Store - stores a value in the map
+func (sm *Sharded[K, V]) Store(key K, value V) {
+	sh := sm.shardFor(key)
+	sh.Lock()
+	defer sh.Unlock()
+
+	sh.m[key] = value
+}
+
+// Delete - deletes a key from the map
+func (sm *Sharded[K, V]) Delete(key K) {
+	sh := sm.shardFor(key)
+	sh.Lock()
+	defer sh.Unlock()
+
+	delete(sh.m, key)
+}
+
+// LoadAndDelete - loads a value from the map and deletes the key
+func (sm *Sharded[K, V]) LoadAndDelete(key K) (value V, ok bool) {
+	sh := sm.shardFor(key)
+	sh.Lock()
+	defer sh.Unlock()
+
+	value, ok = sh.m[key]
+	if ok {
+		delete(sh.m, key)
+	}
+	return value, ok
+}
+
+// LoadOrStore - loads a value from the map or stores a new value
+func (sm *Sharded[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	sh := sm.shardFor(key)
+	sh.Lock()
+	defer sh.Unlock()
+
+	actual, loaded = sh.m[key]
+	if loaded {
+		return actual, true
+	}
+
+	sh.m[key] = value
+	return value, false
+}
+
+// Swap - swaps a value in the map
+func (sm *Sharded[K, V]) Swap(key K, value V) (previous V, loaded bool) {
+	sh := sm.shardFor(key)
+	sh.Lock()
+	defer sh.Unlock()
+
+	previous, loaded = sh.m[key]
+	sh.m[key] = value
+	return previous, loaded
+}
+
+// Range - ranges over the map by calling a function for each key-value
+// pair. Shards are visited sequentially, each under its own RLock, so Range
+// never blocks writers on shards it isn't currently visiting.
+func (sm *Sharded[K, V]) Range(f func(K, V) bool) {
+	for _, sh := range sm.shards {
+		if !sh.rangeOne(f) {
+			return
+		}
+	}
+}
+
+// rangeOne ranges over a single shard, returning false if f asked to stop.
+func (sh *shard[K, V]) rangeOne(f func(K, V) bool) bool {
+	sh.RLock()
+	defer sh.RUnlock()
+
+	for k, v := range sh.m {
+		if !f(k, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clear - clears the map
+func (sm *Sharded[K, V]) Clear() {
+	for _, sh := range sm.shards {
+		sh.Lock()
+		sh.m = make(map[K]V)
+		sh.Unlock()
+	}
+}
+
+// Len - returns the length of the map
+func (sm *Sharded[K, V]) Len() int {
+	total := 0
+	for _, sh := range sm.shards {
+		sh.RLock()
+		total += len(sh.m)
+		sh.RUnlock()
+	}
+	return total
+}
@@ -0,0 +1,123 @@
+package safemap
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSharded_StoreAndLoad(t *testing.T) {
+	sm := NewSharded[int, string](8)
+	sm.Store(1, "one")
+
+	value, ok := sm.Load(1)
+	if !ok || value != "one" {
+		t.Errorf("expected 'one', got '%v'", value)
+	}
+}
+
+func TestSharded_Delete(t *testing.T) {
+	sm := NewSharded[int, string](8)
+	sm.Store(1, "one")
+	sm.Delete(1)
+
+	if sm.Exists(1) {
+		t.Errorf("expected key 1 to be deleted")
+	}
+}
+
+func TestSharded_LoadOrStore(t *testing.T) {
+	sm := NewSharded[int, string](8)
+	value, loaded := sm.LoadOrStore(1, "one")
+	if loaded || value != "one" {
+		t.Errorf("expected 'one', got '%v'", value)
+	}
+
+	value, loaded = sm.LoadOrStore(1, "two")
+	if !loaded || value != "one" {
+		t.Errorf("expected 'one', got '%v'", value)
+	}
+}
+
+func TestSharded_LenAndClear(t *testing.T) {
+	sm := NewSharded[int, string](8)
+	for i := 0; i < 100; i++ {
+		sm.Store(i, "value")
+	}
+
+	if sm.Len() != 100 {
+		t.Errorf("expected length 100, got %d", sm.Len())
+	}
+
+	sm.Clear()
+	if sm.Len() != 0 {
+		t.Errorf("expected length 0, got %d", sm.Len())
+	}
+}
+
+func TestSharded_Range(t *testing.T) {
+	sm := NewSharded[int, string](8)
+	for i := 0; i < 100; i++ {
+		sm.Store(i, "value")
+	}
+
+	seen := make(map[int]bool)
+	sm.Range(func(k int, v string) bool {
+		seen[k] = true
+		return true
+	})
+
+	if len(seen) != 100 {
+		t.Errorf("expected 100 keys, got %d", len(seen))
+	}
+}
+
+func benchmarkShardedStore(b *testing.B, shards, goroutines int) {
+	sm := NewSharded[int, string](shards)
+	b.ResetTimer()
+	b.SetParallelism(goroutines)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			sm.Store(i, "value")
+			i++
+		}
+	})
+}
+
+func benchmarkMapStore(b *testing.B, goroutines int) {
+	sm := New[int, string]()
+	b.ResetTimer()
+	b.SetParallelism(goroutines)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			sm.Store(i, "value")
+			i++
+		}
+	})
+}
+
+func benchmarkSyncMapStore(b *testing.B, goroutines int) {
+	var sm sync.Map
+	b.ResetTimer()
+	b.SetParallelism(goroutines)
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			sm.Store(i, "value")
+			i++
+		}
+	})
+}
+
+func BenchmarkSharded_Store_4(b *testing.B)  { benchmarkShardedStore(b, 16, 4) }
+func BenchmarkSharded_Store_16(b *testing.B) { benchmarkShardedStore(b, 16, 16) }
+func BenchmarkSharded_Store_64(b *testing.B) { benchmarkShardedStore(b, 16, 64) }
+
+func BenchmarkSafeMap_Store_4(b *testing.B)  { benchmarkMapStore(b, 4) }
+func BenchmarkSafeMap_Store_16(b *testing.B) { benchmarkMapStore(b, 16) }
+func BenchmarkSafeMap_Store_64(b *testing.B) { benchmarkMapStore(b, 64) }
+
+func BenchmarkSyncMap_Store_4(b *testing.B)  { benchmarkSyncMapStore(b, 4) }
+func BenchmarkSyncMap_Store_16(b *testing.B) { benchmarkSyncMapStore(b, 16) }
+func BenchmarkSyncMap_Store_64(b *testing.B) { benchmarkSyncMapStore(b, 64) }
@@ -0,0 +1,107 @@
+package safemap
+
+import (
+	"cmp"
+	"hash/maphash"
+)
+
+// Snapshot is an immutable, point-in-time view of an M, captured by
+// M.Snapshot. It is backed by a persistent hash-array-mapped trie, so
+// Load, Range, Len and Keys never take a lock and are never blocked by
+// concurrent mutations on the live M, nor do they block those mutations.
+type Snapshot[K cmp.Ordered, V any] struct {
+	root *hamtNode[K, V]
+	seed maphash.Seed
+}
+
+// Load - loads a value from the snapshot
+func (s *Snapshot[K, V]) Load(key K) (value V, ok bool) {
+	return hamtGet(s.root, hashKey(key, s.seed), 0, key)
+}
+
+// Range - ranges over the snapshot by calling a function for each
+// key-value pair. Iteration order is unspecified.
+func (s *Snapshot[K, V]) Range(f func(K, V) bool) {
+	hamtRange(s.root, f)
+}
+
+// Len - returns the number of entries in the snapshot
+func (s *Snapshot[K, V]) Len() int {
+	n := 0
+	hamtRange(s.root, func(K, V) bool {
+		n++
+		return true
+	})
+	return n
+}
+
+// Keys - returns the keys of the snapshot
+func (s *Snapshot[K, V]) Keys() []K {
+	keys := make([]K, 0)
+	hamtRange(s.root, func(k K, _ V) bool {
+		keys = append(keys, k)
+		return true
+	})
+	return keys
+}
+
+// Snapshot returns a cheap, immutable, point-in-time view of the map. On
+// its first call for a given M, it builds a persistent hash-array-mapped
+// trie mirroring the current contents (an O(n) conversion, performed once);
+// from then on, every mutator (Store/Delete/LoadOrStore/Swap/CompareAndSwap/
+// CompareAndDelete/Update) additionally path-copies its affected trie nodes
+// (O(log32 n) allocations)
+// so that earlier snapshots keep seeing their original contents. Maps that
+// never call Snapshot never pay this cost: the plain map[K]V remains the
+// only bookkeeping for Store/Load/etc.
+func (sm *M[K, V]) Snapshot() *Snapshot[K, V] {
+	sm.RLock()
+	if sm.snapshotting {
+		snap := &Snapshot[K, V]{root: sm.hamtRoot.Load(), seed: sm.hashSeed}
+		sm.RUnlock()
+		return snap
+	}
+	sm.RUnlock()
+
+	sm.Lock()
+	defer sm.Unlock()
+
+	if !sm.snapshotting {
+		sm.hashSeed = maphash.MakeSeed()
+		root := &hamtNode[K, V]{}
+		for k, v := range sm.m {
+			root = hamtInsertNode(root, hashKey(k, sm.hashSeed), 0, k, v)
+		}
+		sm.hamtRoot.Store(root)
+		sm.snapshotting = true
+	}
+
+	return &Snapshot[K, V]{root: sm.hamtRoot.Load(), seed: sm.hashSeed}
+}
+
+// snapshotInsert path-copies the live hamt (if snapshotting has started)
+// to reflect key being set to value. Must be called with sm's write lock
+// held.
+func (sm *M[K, V]) snapshotInsert(key K, value V) {
+	if !sm.snapshotting {
+		return
+	}
+	sm.hamtRoot.Store(hamtInsertNode(sm.hamtRoot.Load(), hashKey(key, sm.hashSeed), 0, key, value))
+}
+
+// snapshotDelete path-copies the live hamt (if snapshotting has started) to
+// reflect key being removed. Must be called with sm's write lock held.
+func (sm *M[K, V]) snapshotDelete(key K) {
+	if !sm.snapshotting {
+		return
+	}
+	sm.hamtRoot.Store(hamtDeleteNode(sm.hamtRoot.Load(), hashKey(key, sm.hashSeed), 0, key))
+}
+
+// snapshotClear resets the live hamt (if snapshotting has started) to
+// empty. Must be called with sm's write lock held.
+func (sm *M[K, V]) snapshotClear() {
+	if sm.snapshotting {
+		sm.hamtRoot.Store(&hamtNode[K, V]{})
+	}
+}
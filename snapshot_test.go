@@ -0,0 +1,119 @@
+package safemap
+
+import "testing"
+
+func TestSnapshot_IsolatedFromLaterWrites(t *testing.T) {
+	sm := New[int, string]()
+	sm.Store(1, "one")
+	sm.Store(2, "two")
+
+	snap := sm.Snapshot()
+
+	sm.Store(3, "three")
+	sm.Delete(1)
+	sm.Store(2, "TWO")
+
+	if snap.Len() != 2 {
+		t.Errorf("expected snapshot length 2, got %d", snap.Len())
+	}
+
+	if v, ok := snap.Load(1); !ok || v != "one" {
+		t.Errorf("expected snapshot to still see (1, one), got (%v, %v)", v, ok)
+	}
+
+	if v, ok := snap.Load(2); !ok || v != "two" {
+		t.Errorf("expected snapshot to see the old value for 2, got (%v, %v)", v, ok)
+	}
+
+	if _, ok := snap.Load(3); ok {
+		t.Errorf("expected snapshot to not see key 3, added after the snapshot was taken")
+	}
+
+	if sm.Len() != 2 {
+		t.Errorf("expected live map length 2, got %d", sm.Len())
+	}
+}
+
+func TestSnapshot_Range(t *testing.T) {
+	sm := New[int, string]()
+	for i := 0; i < 10; i++ {
+		sm.Store(i, "value")
+	}
+
+	snap := sm.Snapshot()
+
+	seen := make(map[int]bool)
+	snap.Range(func(k int, v string) bool {
+		seen[k] = true
+		return true
+	})
+
+	if len(seen) != 10 {
+		t.Errorf("expected 10 keys, got %d", len(seen))
+	}
+}
+
+func TestSnapshot_Keys(t *testing.T) {
+	sm := New[int, string]()
+	sm.Store(1, "one")
+	sm.Store(2, "two")
+
+	snap := sm.Snapshot()
+	keys := snap.Keys()
+
+	if len(keys) != 2 {
+		t.Errorf("expected 2 keys, got %d", len(keys))
+	}
+}
+
+func TestSnapshot_IsolatedFromLaterSwaps(t *testing.T) {
+	sm := New[int, string]()
+	sm.Store(1, "one")
+
+	snap := sm.Snapshot()
+
+	sm.Swap(1, "X")
+	sm.Swap(2, "two")
+
+	if v, ok := snap.Load(1); !ok || v != "one" {
+		t.Errorf("expected snapshot to still see (1, one), got (%v, %v)", v, ok)
+	}
+
+	if _, ok := snap.Load(2); ok {
+		t.Errorf("expected snapshot to not see key 2, added via Swap after the snapshot was taken")
+	}
+
+	if snap.Len() != 1 {
+		t.Errorf("expected snapshot length 1, got %d", snap.Len())
+	}
+
+	fresh := sm.Snapshot()
+	if v, ok := fresh.Load(1); !ok || v != "X" {
+		t.Errorf("expected a fresh snapshot to see the swapped value 'X', got (%v, %v)", v, ok)
+	}
+	if fresh.Len() != 2 {
+		t.Errorf("expected fresh snapshot length 2, got %d", fresh.Len())
+	}
+}
+
+func TestSnapshot_MultipleGenerations(t *testing.T) {
+	sm := New[int, string]()
+	sm.Store(1, "one")
+
+	first := sm.Snapshot()
+
+	sm.Store(2, "two")
+	second := sm.Snapshot()
+
+	sm.Store(3, "three")
+
+	if first.Len() != 1 {
+		t.Errorf("expected first snapshot length 1, got %d", first.Len())
+	}
+	if second.Len() != 2 {
+		t.Errorf("expected second snapshot length 2, got %d", second.Len())
+	}
+	if sm.Len() != 3 {
+		t.Errorf("expected live map length 3, got %d", sm.Len())
+	}
+}
@@ -0,0 +1,255 @@
+package safemap
+
+import (
+	"cmp"
+	"math/rand"
+)
+
+// treapNode is a node of the randomized balanced binary search tree used to
+// keep keys in sorted order for SortedKeys-mode maps.
+type treapNode[K cmp.Ordered] struct {
+	key         K
+	priority    uint32
+	left, right *treapNode[K]
+}
+
+func treapRotateRight[K cmp.Ordered](n *treapNode[K]) *treapNode[K] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	return l
+}
+
+func treapRotateLeft[K cmp.Ordered](n *treapNode[K]) *treapNode[K] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	return r
+}
+
+func treapInsert[K cmp.Ordered](n *treapNode[K], key K) *treapNode[K] {
+	if n == nil {
+		return &treapNode[K]{key: key, priority: rand.Uint32()}
+	}
+
+	switch {
+	case key < n.key:
+		n.left = treapInsert(n.left, key)
+		if n.left.priority > n.priority {
+			n = treapRotateRight(n)
+		}
+	case key > n.key:
+		n.right = treapInsert(n.right, key)
+		if n.right.priority > n.priority {
+			n = treapRotateLeft(n)
+		}
+	}
+	return n
+}
+
+func treapDelete[K cmp.Ordered](n *treapNode[K], key K) *treapNode[K] {
+	if n == nil {
+		return nil
+	}
+
+	switch {
+	case key < n.key:
+		n.left = treapDelete(n.left, key)
+	case key > n.key:
+		n.right = treapDelete(n.right, key)
+	default:
+		switch {
+		case n.left == nil:
+			return n.right
+		case n.right == nil:
+			return n.left
+		case n.left.priority > n.right.priority:
+			n = treapRotateRight(n)
+			n.right = treapDelete(n.right, key)
+		default:
+			n = treapRotateLeft(n)
+			n.left = treapDelete(n.left, key)
+		}
+	}
+	return n
+}
+
+func treapFloor[K cmp.Ordered](n *treapNode[K], key K) (best K, found bool) {
+	for n != nil {
+		switch {
+		case n.key == key:
+			return n.key, true
+		case n.key < key:
+			best, found = n.key, true
+			n = n.right
+		default:
+			n = n.left
+		}
+	}
+	return best, found
+}
+
+func treapCeiling[K cmp.Ordered](n *treapNode[K], key K) (best K, found bool) {
+	for n != nil {
+		switch {
+		case n.key == key:
+			return n.key, true
+		case n.key > key:
+			best, found = n.key, true
+			n = n.left
+		default:
+			n = n.right
+		}
+	}
+	return best, found
+}
+
+func treapFirst[K cmp.Ordered](n *treapNode[K]) (key K, found bool) {
+	if n == nil {
+		return key, false
+	}
+	for n.left != nil {
+		n = n.left
+	}
+	return n.key, true
+}
+
+func treapLast[K cmp.Ordered](n *treapNode[K]) (key K, found bool) {
+	if n == nil {
+		return key, false
+	}
+	for n.right != nil {
+		n = n.right
+	}
+	return n.key, true
+}
+
+// treapRange walks the keys of n that fall in [from, to), in ascending
+// order, calling f(key, value) for each. It stops and returns false as soon
+// as f returns false.
+func treapRange[K cmp.Ordered, V any](n *treapNode[K], m map[K]V, from, to K, f func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.key > from {
+		if !treapRange(n.left, m, from, to, f) {
+			return false
+		}
+	}
+	if n.key >= from && n.key < to {
+		if !f(n.key, m[n.key]) {
+			return false
+		}
+	}
+	if n.key < to {
+		if !treapRange(n.right, m, from, to, f) {
+			return false
+		}
+	}
+	return true
+}
+
+// treapInOrder walks every key of n in ascending order, calling
+// f(key, value) for each, stopping as soon as f returns false.
+func treapInOrder[K cmp.Ordered, V any](n *treapNode[K], m map[K]V, f func(K, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if !treapInOrder(n.left, m, f) {
+		return false
+	}
+	if !f(n.key, m[n.key]) {
+		return false
+	}
+	return treapInOrder(n.right, m, f)
+}
+
+// NewSorted creates a new M whose keys are kept in sorted order by a treap
+// instead of the insertion-order list used by M(Ordered). This enables
+// KeysInRange, RangeInRange, Floor, Ceiling, First and Last in O(log n + k)
+// instead of M's O(n) scan. Range and Keys walk the treap, so they visit
+// keys in ascending key order. The insertion-order list is still kept and
+// the map is created with Ordered set, so RangeDelete visits keys in
+// insertion order.
+func NewSorted[K cmp.Ordered, V any]() *M[K, V] {
+	sm := New[K, V](Ordered)
+	sm.sortedKeys = true
+	return sm
+}
+
+// RangeInRange - like Range, but only visits keys in [from, to), in
+// ascending key order, in O(log n + k). Only valid for maps created with
+// NewSorted; for other maps it returns immediately without calling f.
+func (sm *M[K, V]) RangeInRange(from, to K, f func(K, V) bool) {
+	sm.RLock()
+	defer sm.RUnlock()
+
+	if !sm.sortedKeys {
+		return
+	}
+	treapRange(sm.tree, sm.m, from, to, f)
+}
+
+// Floor - returns the greatest key less than or equal to key, and its
+// value. Only valid for maps created with NewSorted.
+func (sm *M[K, V]) Floor(key K) (k K, v V, ok bool) {
+	sm.RLock()
+	defer sm.RUnlock()
+
+	if !sm.sortedKeys {
+		return k, v, false
+	}
+	k, ok = treapFloor(sm.tree, key)
+	if !ok {
+		return k, v, false
+	}
+	return k, sm.m[k], true
+}
+
+// Ceiling - returns the smallest key greater than or equal to key, and its
+// value. Only valid for maps created with NewSorted.
+func (sm *M[K, V]) Ceiling(key K) (k K, v V, ok bool) {
+	sm.RLock()
+	defer sm.RUnlock()
+
+	if !sm.sortedKeys {
+		return k, v, false
+	}
+	k, ok = treapCeiling(sm.tree, key)
+	if !ok {
+		return k, v, false
+	}
+	return k, sm.m[k], true
+}
+
+// First - returns the smallest key in the map, and its value. Only valid
+// for maps created with NewSorted.
+func (sm *M[K, V]) First() (k K, v V, ok bool) {
+	sm.RLock()
+	defer sm.RUnlock()
+
+	if !sm.sortedKeys {
+		return k, v, false
+	}
+	k, ok = treapFirst(sm.tree)
+	if !ok {
+		return k, v, false
+	}
+	return k, sm.m[k], true
+}
+
+// Last - returns the greatest key in the map, and its value. Only valid for
+// maps created with NewSorted.
+func (sm *M[K, V]) Last() (k K, v V, ok bool) {
+	sm.RLock()
+	defer sm.RUnlock()
+
+	if !sm.sortedKeys {
+		return k, v, false
+	}
+	k, ok = treapLast(sm.tree)
+	if !ok {
+		return k, v, false
+	}
+	return k, sm.m[k], true
+}
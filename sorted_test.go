@@ -0,0 +1,179 @@
+package safemap
+
+import "testing"
+
+func TestSorted_StoreAndOrder(t *testing.T) {
+	sm := NewSorted[int, string]()
+	sm.Store(3, "three")
+	sm.Store(1, "one")
+	sm.Store(2, "two")
+
+	var got []int
+	sm.Range(func(k int, v string) bool {
+		got = append(got, k)
+		return true
+	})
+
+	want := []int{1, 2, 3}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, got)
+			break
+		}
+	}
+}
+
+func TestSorted_Delete(t *testing.T) {
+	sm := NewSorted[int, string]()
+	sm.Store(1, "one")
+	sm.Store(2, "two")
+	sm.Delete(1)
+
+	if sm.Exists(1) {
+		t.Errorf("expected key 1 to be deleted")
+	}
+
+	_, _, ok := sm.Floor(1)
+	if ok {
+		t.Errorf("expected no floor for 1 after deletion")
+	}
+}
+
+func TestSorted_KeysInRange(t *testing.T) {
+	sm := NewSorted[int, string]()
+	for i := 1; i <= 5; i++ {
+		sm.Store(i, "value")
+	}
+
+	keys := sm.KeysInRange(2, 4)
+	if len(keys) != 2 || keys[0] != 2 || keys[1] != 3 {
+		t.Errorf("expected [2 3], got %v", keys)
+	}
+}
+
+func TestSorted_RangeInRange(t *testing.T) {
+	sm := NewSorted[int, string]()
+	for i := 1; i <= 5; i++ {
+		sm.Store(i, "value")
+	}
+
+	var keys []int
+	sm.RangeInRange(2, 4, func(k int, v string) bool {
+		keys = append(keys, k)
+		return true
+	})
+
+	if len(keys) != 2 || keys[0] != 2 || keys[1] != 3 {
+		t.Errorf("expected [2 3], got %v", keys)
+	}
+}
+
+func TestSorted_FloorAndCeiling(t *testing.T) {
+	sm := NewSorted[int, string]()
+	sm.Store(10, "ten")
+	sm.Store(20, "twenty")
+	sm.Store(30, "thirty")
+
+	k, v, ok := sm.Floor(25)
+	if !ok || k != 20 || v != "twenty" {
+		t.Errorf("expected (20, twenty), got (%v, %v, %v)", k, v, ok)
+	}
+
+	k, v, ok = sm.Ceiling(25)
+	if !ok || k != 30 || v != "thirty" {
+		t.Errorf("expected (30, thirty), got (%v, %v, %v)", k, v, ok)
+	}
+
+	if _, _, ok := sm.Floor(5); ok {
+		t.Errorf("expected no floor below the smallest key")
+	}
+
+	if _, _, ok := sm.Ceiling(35); ok {
+		t.Errorf("expected no ceiling above the largest key")
+	}
+}
+
+func TestSorted_FirstAndLast(t *testing.T) {
+	sm := NewSorted[int, string]()
+	sm.Store(10, "ten")
+	sm.Store(20, "twenty")
+	sm.Store(30, "thirty")
+
+	k, v, ok := sm.First()
+	if !ok || k != 10 || v != "ten" {
+		t.Errorf("expected (10, ten), got (%v, %v, %v)", k, v, ok)
+	}
+
+	k, v, ok = sm.Last()
+	if !ok || k != 30 || v != "thirty" {
+		t.Errorf("expected (30, thirty), got (%v, %v, %v)", k, v, ok)
+	}
+}
+
+func TestSorted_Swap(t *testing.T) {
+	sm := NewSorted[int, string]()
+	sm.Store(1, "one")
+	sm.Swap(2, "two")
+
+	keys := sm.Keys()
+	if len(keys) != 2 || keys[0] != 1 || keys[1] != 2 {
+		t.Errorf("expected [1 2], got %v", keys)
+	}
+
+	k, _, ok := sm.Last()
+	if !ok || k != 2 {
+		t.Errorf("expected last key 2, got %v", k)
+	}
+
+	previous, loaded := sm.Swap(2, "TWO")
+	if !loaded || previous != "two" {
+		t.Errorf("expected ('two', true), got ('%v', %v)", previous, loaded)
+	}
+
+	keys = sm.Keys()
+	if len(keys) != 2 {
+		t.Errorf("expected 2 keys after swapping an existing one, got %v", keys)
+	}
+}
+
+func TestSorted_ModeSpecificMethodsNoopForOtherMaps(t *testing.T) {
+	sm := New[int, string]()
+	sm.Store(1, "one")
+
+	if _, _, ok := sm.First(); ok {
+		t.Errorf("expected First to report no result for a non-sorted map")
+	}
+}
+
+func benchmarkSortedKeysInRange(b *testing.B, n int) {
+	sm := NewSorted[int, struct{}]()
+	for i := 0; i < n; i++ {
+		sm.Store(i, struct{}{})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sm.KeysInRange(n/4, n/4*3)
+	}
+}
+
+func benchmarkLinearScanKeysInRange(b *testing.B, n int) {
+	sm := New[int, struct{}]()
+	for i := 0; i < n; i++ {
+		sm.Store(i, struct{}{})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sm.KeysInRange(n/4, n/4*3)
+	}
+}
+
+func BenchmarkSorted_KeysInRange_10k(b *testing.B)  { benchmarkSortedKeysInRange(b, 10_000) }
+func BenchmarkSorted_KeysInRange_100k(b *testing.B) { benchmarkSortedKeysInRange(b, 100_000) }
+
+func BenchmarkLinearScan_KeysInRange_10k(b *testing.B)  { benchmarkLinearScanKeysInRange(b, 10_000) }
+func BenchmarkLinearScan_KeysInRange_100k(b *testing.B) { benchmarkLinearScanKeysInRange(b, 100_000) }